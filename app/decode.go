@@ -0,0 +1,77 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/gilcrest/go-api-basic/domain/errs"
+)
+
+// decodeConfig holds the options a DecodeOption can set on DecodeJSON.
+type decodeConfig struct {
+	disallowUnknownFields bool
+	maxBytes              int64
+	requireContentType    string
+}
+
+// DecodeOption configures DecodeJSON.
+type DecodeOption func(*decodeConfig)
+
+// DisallowUnknownFields causes DecodeJSON to reject a request body
+// containing a field that does not exist on v.
+func DisallowUnknownFields() DecodeOption {
+	return func(c *decodeConfig) { c.disallowUnknownFields = true }
+}
+
+// MaxBytes caps the size, in bytes, of the request body DecodeJSON will
+// read. A body larger than n causes DecodeJSON to return an
+// *errs.Error with Kind errs.InvalidRequest.
+func MaxBytes(n int64) DecodeOption {
+	return func(c *decodeConfig) { c.maxBytes = n }
+}
+
+// RequireContentType causes DecodeJSON to reject a request whose
+// Content-Type header does not start with ct. The default, set by
+// DecodeJSON itself, is "application/json"; pass "" to accept any
+// Content-Type (including none).
+func RequireContentType(ct string) DecodeOption {
+	return func(c *decodeConfig) { c.requireContentType = ct }
+}
+
+// DecodeJSON decodes the JSON body of r into v, applying any opts, and
+// returns an *errs.Error with Kind errs.InvalidRequest describing what
+// went wrong if the body is missing, malformed, oversized, or sent
+// with the wrong Content-Type. w is only used to enforce MaxBytes via
+// http.MaxBytesReader; DecodeJSON never writes to it.
+func DecodeJSON(w http.ResponseWriter, r *http.Request, v interface{}, opts ...DecodeOption) error {
+	const op errs.Op = "app/DecodeJSON"
+
+	cfg := decodeConfig{requireContentType: "application/json"}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+
+	if cfg.requireContentType != "" {
+		if ct := r.Header.Get("Content-Type"); !strings.HasPrefix(ct, cfg.requireContentType) {
+			return errs.E(op, errs.InvalidRequest, fmt.Errorf("Content-Type must be %s, got %q", cfg.requireContentType, ct))
+		}
+	}
+
+	body := r.Body
+	if cfg.maxBytes > 0 {
+		body = http.MaxBytesReader(w, r.Body, cfg.maxBytes)
+	}
+
+	dec := json.NewDecoder(body)
+	if cfg.disallowUnknownFields {
+		dec.DisallowUnknownFields()
+	}
+
+	if err := dec.Decode(v); err != nil {
+		return errs.E(op, decoderErr(err))
+	}
+
+	return nil
+}
@@ -0,0 +1,129 @@
+package app
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/go-api-basic/domain/errs"
+	"github.com/gilcrest/go-api-basic/domain/logger"
+)
+
+type decodeTestBody struct {
+	Director string `json:"director"`
+}
+
+// problemBody mirrors the RFC 7807 shape written by
+// errs.HTTPErrorResponse, for use in assertions below.
+type problemBody struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// decodeAndRespond runs DecodeJSON and, on error, writes it through
+// errs.HTTPErrorResponse exactly as a handler would.
+func decodeAndRespond(lgr zerolog.Logger, w http.ResponseWriter, r *http.Request, opts ...DecodeOption) error {
+	var body decodeTestBody
+	err := DecodeJSON(w, r, &body, opts...)
+	if err != nil {
+		errs.HTTPErrorResponse(w, r, lgr, err)
+	}
+	return err
+}
+
+func TestDecodeJSON_ProblemJSON(t *testing.T) {
+	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
+
+	tests := []struct {
+		name        string
+		requestBody string
+		contentType string
+		opts        []DecodeOption
+		wantStatus  int
+		wantDetail  string
+	}{
+		{
+			name:        "malformed JSON",
+			requestBody: `{"director": "Alex Cox"`,
+			contentType: "application/json",
+			wantStatus:  http.StatusBadRequest,
+			wantDetail:  "app/DecodeJSON: Malformed JSON",
+		},
+		{
+			name:        "empty body",
+			requestBody: "",
+			contentType: "application/json",
+			wantStatus:  http.StatusBadRequest,
+			wantDetail:  "app/DecodeJSON: Request Body cannot be empty",
+		},
+		{
+			name:        "unknown field",
+			requestBody: `{"director": "Alex Cox", "unknown_field": "nope"}`,
+			contentType: "application/json",
+			opts:        []DecodeOption{DisallowUnknownFields()},
+			wantStatus:  http.StatusBadRequest,
+			wantDetail:  `app/DecodeJSON: json: unknown field "unknown_field"`,
+		},
+		{
+			name:        "oversized body",
+			requestBody: `{"director": "Alex Cox"}`,
+			contentType: "application/json",
+			opts:        []DecodeOption{MaxBytes(4)},
+			wantStatus:  http.StatusBadRequest,
+			wantDetail:  "app/DecodeJSON: Request Body too large",
+		},
+		{
+			name:        "wrong content type",
+			requestBody: `{"director": "Alex Cox"}`,
+			contentType: "text/plain",
+			wantStatus:  http.StatusBadRequest,
+			wantDetail:  `app/DecodeJSON: Content-Type must be application/json, got "text/plain"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodPost, "/fake", bytes.NewBufferString(tt.requestBody))
+			r.Header.Set("Content-Type", tt.contentType)
+			r.Header.Set("Accept", "application/problem+json")
+			w := httptest.NewRecorder()
+
+			err := decodeAndRespond(lgr, w, r, tt.opts...)
+			if err == nil {
+				t.Fatal("DecodeJSON() error = nil, want non-nil")
+			}
+
+			if got := w.Header().Get("Content-Type"); !strings.HasPrefix(got, "application/problem+json") {
+				t.Errorf("Content-Type = %q, want application/problem+json", got)
+			}
+			if w.Code != tt.wantStatus {
+				t.Errorf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+
+			var got problemBody
+			if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+				t.Fatalf("json.Unmarshal(body) error = %v, body = %s", err, w.Body.String())
+			}
+
+			want := problemBody{
+				Type:     "urn:go-api-basic:error:invalid_request",
+				Title:    "Bad Request",
+				Status:   tt.wantStatus,
+				Detail:   tt.wantDetail,
+				Instance: "/fake",
+			}
+			if got != want {
+				t.Errorf("problem body = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,152 @@
+// Package app wires together the HTTP router, the database driver and
+// the logger into a Server that can be handed to http.ListenAndServe.
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/go-api-basic/auth"
+	"github.com/gilcrest/go-api-basic/datastore"
+	"github.com/gilcrest/go-api-basic/domain/errs"
+)
+
+// Driver identifies the database driver the Server was configured
+// with. It exists as its own type (rather than a bare string) so that
+// callers cannot accidentally wire up a Server without going through a
+// constructor such as NewDriver.
+type Driver interface {
+	// Name returns the database/sql driver name registered for this Driver.
+	Name() string
+}
+
+// pqDriver is the Driver implementation backed by github.com/lib/pq.
+type pqDriver struct{}
+
+func (pqDriver) Name() string { return "postgres" }
+
+// NewDriver returns the default Driver used by the application.
+func NewDriver() Driver {
+	return pqDriver{}
+}
+
+// NewMuxRouter returns a new, empty gorilla/mux Router.
+func NewMuxRouter() *mux.Router {
+	return mux.NewRouter()
+}
+
+// ServerParams are the parameters required to construct a Server.
+type ServerParams struct {
+	Logger zerolog.Logger
+	Driver Driver
+
+	// TokenStore, if set, causes NewServer to install bearer-token auth
+	// middleware on the router. GET/HEAD requests are public by default;
+	// every other method requires an Authorization: Bearer <token>
+	// header with a token known to TokenStore. Leave nil to run without
+	// authentication.
+	TokenStore *auth.TokenStore
+
+	// PublicRoutes overrides the default GET/HEAD-is-public behavior on
+	// a per mux.Route-name basis. It is only consulted when TokenStore
+	// is set.
+	PublicRoutes map[string]bool
+
+	// DB, if set, is opened via datastore.NewDatastoreFromDriver and
+	// made available as Server.Datastore. This lets callers choose the
+	// underlying database driver (e.g. datastore.PostgreSQLDSN for
+	// production, datastore.SQLiteDSN for tests) without the app
+	// package needing to know about either.
+	DB datastore.Driver
+}
+
+// NewServerParams is a constructor for ServerParams.
+func NewServerParams(logger zerolog.Logger, driver Driver) *ServerParams {
+	return &ServerParams{Logger: logger, Driver: driver}
+}
+
+// Server is the top-level type for the API. It holds the router that
+// routes are registered on as well as the dependencies (driver, logger)
+// those routes are handed.
+type Server struct {
+	router    *mux.Router
+	driver    Driver
+	logger    zerolog.Logger
+	datastore datastore.Datastore
+	dbCleanup func()
+}
+
+// NewServer is a constructor for Server. It validates params, stores the
+// router and dependencies, opens params.DB if set and installs the auth
+// middleware if params.TokenStore is set. It registers no routes on r.
+func NewServer(r *mux.Router, params *ServerParams) (*Server, error) {
+	if params == nil {
+		return nil, errs.E("params must not be nil")
+	}
+	if params.Driver == nil {
+		return nil, errs.E("params.Driver must not be nil")
+	}
+
+	s := &Server{
+		router:    r,
+		driver:    params.Driver,
+		logger:    params.Logger,
+		dbCleanup: func() {},
+	}
+
+	if params.DB != nil {
+		ds, cleanup, err := datastore.NewDatastoreFromDriver(params.DB, params.Logger)
+		if err != nil {
+			cleanup()
+			return nil, errs.E(err)
+		}
+		s.datastore = ds
+		s.dbCleanup = cleanup
+	}
+
+	if params.TokenStore != nil {
+		s.router.Use(auth.Middleware(*params.TokenStore, params.Logger, params.PublicRoutes))
+	}
+
+	return s, nil
+}
+
+// Router returns the Server's mux.Router.
+func (s *Server) Router() *mux.Router {
+	return s.router
+}
+
+// Datastore returns the Server's datastore.Datastore. It is the zero
+// value unless ServerParams.DB was set.
+func (s *Server) Datastore() datastore.Datastore {
+	return s.datastore
+}
+
+// Close releases the Server's database connection pool, if one was
+// opened.
+func (s *Server) Close() {
+	s.dbCleanup()
+}
+
+// decoderErr inspects the error returned from a json.Decoder's Decode
+// method and translates it into an *errs.Error with Kind InvalidRequest,
+// carrying a message appropriate for returning to the caller.
+func decoderErr(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case errors.Is(err, io.EOF):
+		return errs.E(errs.InvalidRequest, errors.New("Request Body cannot be empty"))
+	case errors.As(err, new(*json.SyntaxError)), errors.Is(err, io.ErrUnexpectedEOF):
+		return errs.E(errs.InvalidRequest, errors.New("Malformed JSON"))
+	case strings.Contains(err.Error(), "http: request body too large"):
+		return errs.E(errs.InvalidRequest, errors.New("Request Body too large"))
+	default:
+		return errs.E(errs.InvalidRequest, err)
+	}
+}
@@ -0,0 +1,74 @@
+package auth
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/go-api-basic/domain/errs"
+)
+
+// bearerPrefix is the Authorization header scheme this package expects.
+const bearerPrefix = "Bearer "
+
+// Middleware returns HTTP middleware that validates a bearer token
+// against store. GET and HEAD requests are public by default; every
+// other method requires a valid token. publicRoutes overrides this on
+// a per-route basis: a named route set to true in publicRoutes is
+// always public, and one set to false always requires a token,
+// regardless of method. Routes must be named (mux.Route.Name) for an
+// entry in publicRoutes to take effect.
+func Middleware(store TokenStore, lgr zerolog.Logger, publicRoutes map[string]bool) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if !requiresAuth(r, publicRoutes) {
+				next.ServeHTTP(w, r)
+				return
+			}
+
+			token := bearerToken(r)
+			if token == "" {
+				errs.HTTPErrorResponse(w, r, lgr, errs.E(errs.Unauthenticated, "Authorization: Bearer token is required"))
+				return
+			}
+
+			if _, err := store.ValidateToken(r.Context(), token); err != nil {
+				errs.HTTPErrorResponse(w, r, lgr, err)
+				return
+			}
+
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// requiresAuth reports whether r must carry a valid bearer token.
+func requiresAuth(r *http.Request, publicRoutes map[string]bool) bool {
+	if name := routeName(r); name != "" {
+		if isPublic, ok := publicRoutes[name]; ok {
+			return !isPublic
+		}
+	}
+	return r.Method != http.MethodGet && r.Method != http.MethodHead
+}
+
+// routeName returns the name of the mux.Route that matched r, or "" if
+// the request was not routed through mux or the route is unnamed.
+func routeName(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		return route.GetName()
+	}
+	return ""
+}
+
+// bearerToken extracts the token from the Authorization header, or
+// returns "" if the header is absent or not a Bearer scheme.
+func bearerToken(r *http.Request) string {
+	h := r.Header.Get("Authorization")
+	if !strings.HasPrefix(h, bearerPrefix) {
+		return ""
+	}
+	return strings.TrimPrefix(h, bearerPrefix)
+}
@@ -0,0 +1,137 @@
+// Package auth provides a minimal, database-backed bearer-token
+// authentication scheme: a TokenStore issues and validates opaque
+// tokens for a user, persisted in the users and tokens tables, and
+// Middleware enforces their presence on mutating HTTP requests.
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/gilcrest/go-api-basic/datastore"
+	"github.com/gilcrest/go-api-basic/domain/errs"
+)
+
+// TokenStore issues and validates bearer tokens, backed by the users
+// and tokens tables in the application datastore. Its queries are
+// built through ds.Placeholder so they run unchanged against either
+// dialect this package's Datastore may be opened with.
+//
+//	CREATE TABLE users (
+//		id    bigserial PRIMARY KEY,
+//		email text NOT NULL UNIQUE
+//	);
+//	CREATE TABLE tokens (
+//		token   text NOT NULL PRIMARY KEY,
+//		user_id bigint NOT NULL REFERENCES users (id)
+//	);
+type TokenStore struct {
+	ds datastore.Datastore
+}
+
+// NewTokenStore is a constructor for TokenStore.
+func NewTokenStore(ds datastore.Datastore) TokenStore {
+	return TokenStore{ds: ds}
+}
+
+// AddUser creates a user row for email, if one does not already exist,
+// and issues a new bearer token for it. The token is returned in plain
+// text; only its hash is persisted, so it cannot be recovered once
+// issued.
+func (s TokenStore) AddUser(ctx context.Context, email string) (token string, err error) {
+	const op errs.Op = "auth/TokenStore.AddUser"
+
+	if email == "" {
+		return "", errs.E(op, errs.InvalidRequest, "email must not be empty")
+	}
+
+	token, err = newToken()
+	if err != nil {
+		return "", errs.E(op, errs.Internal, err)
+	}
+
+	tx, err := s.ds.BeginTx(ctx)
+	if err != nil {
+		return "", errs.E(op, err)
+	}
+
+	var userID int64
+	upsertUser := fmt.Sprintf(
+		`INSERT INTO users (email) VALUES (%s)
+		 ON CONFLICT (email) DO UPDATE SET email = EXCLUDED.email
+		 RETURNING id`, s.ds.Placeholder(1))
+	err = tx.QueryRowContext(ctx, upsertUser, email).Scan(&userID)
+	if err != nil {
+		return "", s.ds.RollbackTx(tx, errs.E(op, errs.Database, err))
+	}
+
+	insertToken := fmt.Sprintf(
+		`INSERT INTO tokens (token, user_id) VALUES (%s, %s)`, s.ds.Placeholder(1), s.ds.Placeholder(2))
+	_, err = tx.ExecContext(ctx, insertToken, hashToken(token), userID)
+	if err != nil {
+		return "", s.ds.RollbackTx(tx, errs.E(op, errs.Database, err))
+	}
+
+	if err = s.ds.CommitTx(tx); err != nil {
+		return "", errs.E(op, err)
+	}
+
+	return token, nil
+}
+
+// RevokeToken deletes token from the tokens table so that it is no
+// longer accepted by ValidateToken. Revoking an unknown token is not
+// an error.
+func (s TokenStore) RevokeToken(ctx context.Context, token string) error {
+	const op errs.Op = "auth/TokenStore.RevokeToken"
+
+	query := fmt.Sprintf(`DELETE FROM tokens WHERE token = %s`, s.ds.Placeholder(1))
+	_, err := s.ds.DB().ExecContext(ctx, query, hashToken(token))
+	if err != nil {
+		return errs.E(op, errs.Database, err)
+	}
+
+	return nil
+}
+
+// ValidateToken looks up token and returns the id of the user it was
+// issued to. It returns an *errs.Error with Kind errs.Unauthenticated
+// if the token is missing, malformed, or has been revoked.
+func (s TokenStore) ValidateToken(ctx context.Context, token string) (userID int64, err error) {
+	const op errs.Op = "auth/TokenStore.ValidateToken"
+
+	if token == "" {
+		return 0, errs.E(op, errs.Unauthenticated, "bearer token must not be empty")
+	}
+
+	query := fmt.Sprintf(`SELECT user_id FROM tokens WHERE token = %s`, s.ds.Placeholder(1))
+	row := s.ds.DB().QueryRowContext(ctx, query, hashToken(token))
+	switch err = row.Scan(&userID); {
+	case err == sql.ErrNoRows:
+		return 0, errs.E(op, errs.Unauthenticated, "invalid or revoked token")
+	case err != nil:
+		return 0, errs.E(op, errs.Database, err)
+	}
+
+	return userID, nil
+}
+
+// newToken generates a random bearer token, hex encoded.
+func newToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("rand.Read: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
+
+// hashToken is applied to a token before it is stored or looked up, so
+// that a database leak does not expose usable bearer tokens.
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,132 @@
+package datastore
+
+import (
+	"database/sql"
+	"database/sql/driver"
+
+	"github.com/lib/pq"
+)
+
+// NullStringArray wraps lib/pq's StringArray so that handlers can bind
+// Postgres text[] columns the same way NewNullString binds text.
+type NullStringArray struct {
+	pq.StringArray
+}
+
+// NewNullStringArray returns a NullStringArray for ss. A nil or empty
+// slice is stored as NULL, mirroring NewNullString's treatment of "".
+func NewNullStringArray(ss []string) NullStringArray {
+	if len(ss) == 0 {
+		return NullStringArray{}
+	}
+	return NullStringArray{StringArray: pq.StringArray(ss)}
+}
+
+// Scan implements sql.Scanner, passing through to pq.StringArray.
+func (a *NullStringArray) Scan(src interface{}) error {
+	return a.StringArray.Scan(src)
+}
+
+// Value implements driver.Valuer, returning NULL for an empty array.
+func (a NullStringArray) Value() (driver.Value, error) {
+	if len(a.StringArray) == 0 {
+		return nil, nil
+	}
+	return a.StringArray.Value()
+}
+
+// NullInt64Array wraps lib/pq's Int64Array so that handlers can bind
+// Postgres int[] columns the same way NewNullInt64 binds int.
+type NullInt64Array struct {
+	pq.Int64Array
+}
+
+// NewNullInt64Array returns a NullInt64Array for is. A nil or empty
+// slice is stored as NULL, mirroring NewNullInt64's treatment of 0.
+func NewNullInt64Array(is []int64) NullInt64Array {
+	if len(is) == 0 {
+		return NullInt64Array{}
+	}
+	return NullInt64Array{Int64Array: pq.Int64Array(is)}
+}
+
+// Scan implements sql.Scanner, passing through to pq.Int64Array.
+func (a *NullInt64Array) Scan(src interface{}) error {
+	return a.Int64Array.Scan(src)
+}
+
+// Value implements driver.Valuer, returning NULL for an empty array.
+func (a NullInt64Array) Value() (driver.Value, error) {
+	if len(a.Int64Array) == 0 {
+		return nil, nil
+	}
+	return a.Int64Array.Value()
+}
+
+// NullFloat64Array wraps lib/pq's Float64Array so that handlers can
+// bind Postgres float8[] columns.
+type NullFloat64Array struct {
+	pq.Float64Array
+}
+
+// NewNullFloat64Array returns a NullFloat64Array for fs. A nil or
+// empty slice is stored as NULL.
+func NewNullFloat64Array(fs []float64) NullFloat64Array {
+	if len(fs) == 0 {
+		return NullFloat64Array{}
+	}
+	return NullFloat64Array{Float64Array: pq.Float64Array(fs)}
+}
+
+// Scan implements sql.Scanner, passing through to pq.Float64Array.
+func (a *NullFloat64Array) Scan(src interface{}) error {
+	return a.Float64Array.Scan(src)
+}
+
+// Value implements driver.Valuer, returning NULL for an empty array.
+func (a NullFloat64Array) Value() (driver.Value, error) {
+	if len(a.Float64Array) == 0 {
+		return nil, nil
+	}
+	return a.Float64Array.Value()
+}
+
+// NullBoolArray wraps lib/pq's BoolArray so that handlers can bind
+// Postgres bool[] columns.
+type NullBoolArray struct {
+	pq.BoolArray
+}
+
+// NewNullBoolArray returns a NullBoolArray for bs. A nil or empty
+// slice is stored as NULL.
+func NewNullBoolArray(bs []bool) NullBoolArray {
+	if len(bs) == 0 {
+		return NullBoolArray{}
+	}
+	return NullBoolArray{BoolArray: pq.BoolArray(bs)}
+}
+
+// Scan implements sql.Scanner, passing through to pq.BoolArray.
+func (a *NullBoolArray) Scan(src interface{}) error {
+	return a.BoolArray.Scan(src)
+}
+
+// Value implements driver.Valuer, returning NULL for an empty array.
+func (a NullBoolArray) Value() (driver.Value, error) {
+	if len(a.BoolArray) == 0 {
+		return nil, nil
+	}
+	return a.BoolArray.Value()
+}
+
+// Array is a thin wrapper around lib/pq's Array, re-exported here so
+// that callers need not import lib/pq directly to bind a Go slice to
+// a Postgres array column, e.g.:
+//
+//	ds.DB().Query("... WHERE id = ANY($1)", datastore.Array([]int64{1, 2, 3}))
+func Array(a interface{}) interface {
+	driver.Valuer
+	sql.Scanner
+} {
+	return pq.Array(a)
+}
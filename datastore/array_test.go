@@ -0,0 +1,105 @@
+package datastore
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/lib/pq"
+)
+
+func TestNewNullStringArray(t *testing.T) {
+	type args struct {
+		ss []string
+	}
+	tests := []struct {
+		name string
+		args args
+		want NullStringArray
+	}{
+		{"has value", args{ss: []string{"a", "b"}}, NullStringArray{StringArray: pq.StringArray{"a", "b"}}},
+		{"nil slice", args{ss: nil}, NullStringArray{}},
+		{"empty slice", args{ss: []string{}}, NullStringArray{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewNullStringArray(tt.args.ss); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewNullStringArray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNullInt64Array(t *testing.T) {
+	type args struct {
+		is []int64
+	}
+	tests := []struct {
+		name string
+		args args
+		want NullInt64Array
+	}{
+		{"has value", args{is: []int64{1, 2, 3}}, NullInt64Array{Int64Array: pq.Int64Array{1, 2, 3}}},
+		{"nil slice", args{is: nil}, NullInt64Array{}},
+		{"empty slice", args{is: []int64{}}, NullInt64Array{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewNullInt64Array(tt.args.is); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewNullInt64Array() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNullFloat64Array(t *testing.T) {
+	type args struct {
+		fs []float64
+	}
+	tests := []struct {
+		name string
+		args args
+		want NullFloat64Array
+	}{
+		{"has value", args{fs: []float64{1.1, 2.2}}, NullFloat64Array{Float64Array: pq.Float64Array{1.1, 2.2}}},
+		{"nil slice", args{fs: nil}, NullFloat64Array{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewNullFloat64Array(tt.args.fs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewNullFloat64Array() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewNullBoolArray(t *testing.T) {
+	type args struct {
+		bs []bool
+	}
+	tests := []struct {
+		name string
+		args args
+		want NullBoolArray
+	}{
+		{"has value", args{bs: []bool{true, false}}, NullBoolArray{BoolArray: pq.BoolArray{true, false}}},
+		{"nil slice", args{bs: nil}, NullBoolArray{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NewNullBoolArray(tt.args.bs); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("NewNullBoolArray() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestArray(t *testing.T) {
+	v := Array([]int64{1, 2, 3})
+	val, err := v.Value()
+	if err != nil {
+		t.Fatalf("Value() error = %v", err)
+	}
+	if val == nil {
+		t.Fatalf("Value() = nil, want non-nil driver.Value")
+	}
+}
@@ -0,0 +1,150 @@
+// Package datastore provides the database connection and the small set
+// of helpers (null-type constructors, transaction wrappers) that the
+// rest of the application builds repositories on top of.
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/go-api-basic/domain/errs"
+)
+
+// PostgreSQLDSN holds the connection details for a PostgreSQL database
+// and knows how to render itself as a libpq connection string.
+type PostgreSQLDSN struct {
+	Host     string
+	Port     int
+	DBName   string
+	User     string
+	Password string
+}
+
+// NewPostgreSQLDSN is a constructor for PostgreSQLDSN.
+func NewPostgreSQLDSN(host, dbname, user, password string, port int) PostgreSQLDSN {
+	return PostgreSQLDSN{
+		Host:     host,
+		Port:     port,
+		DBName:   dbname,
+		User:     user,
+		Password: password,
+	}
+}
+
+// String returns the libpq connection string for the DSN. The password
+// field is omitted entirely when empty, rather than being written as
+// password="".
+func (dsn PostgreSQLDSN) String() string {
+	s := fmt.Sprintf("host=%s port=%d dbname=%s user=%s", dsn.Host, dsn.Port, dsn.DBName, dsn.User)
+	if dsn.Password != "" {
+		s = fmt.Sprintf("%s password=%s", s, dsn.Password)
+	}
+	return fmt.Sprintf("%s sslmode=disable", s)
+}
+
+// NewPostgreSQLDB opens a *sql.DB for the given DSN, pings it to verify
+// connectivity and returns it along with a cleanup function that closes
+// the connection pool. It is a thin convenience wrapper around
+// dsn.Open; new code that wants to be driver-agnostic should prefer
+// NewDatastoreFromDriver.
+func NewPostgreSQLDB(dsn PostgreSQLDSN, lgr zerolog.Logger) (db *sql.DB, cleanup func(), err error) {
+	return dsn.Open(lgr)
+}
+
+// Datastore wraps a *sql.DB and provides the transaction helpers and
+// null-type constructors used by repository code.
+type Datastore struct {
+	db      *sql.DB
+	dialect string
+}
+
+// NewDatastore is a constructor for Datastore. Callers that need
+// Dialect to report anything other than "" should use
+// NewDatastoreFromDriver instead.
+func NewDatastore(db *sql.DB) Datastore {
+	return Datastore{db: db}
+}
+
+// DB returns the underlying *sql.DB.
+func (ds Datastore) DB() *sql.DB {
+	return ds.db
+}
+
+// Dialect returns the SQL dialect of the underlying database, e.g.
+// "postgres" or "sqlite", as reported by the Driver the Datastore was
+// built from. It is "" for a Datastore built with NewDatastore.
+func (ds Datastore) Dialect() string {
+	return ds.dialect
+}
+
+// Placeholder returns the bind parameter placeholder for the n'th
+// (1-based) argument of a query, in the Datastore's dialect: "$1",
+// "$2", ... for postgres, "?" for every position in sqlite. Repository
+// code that builds SQL with positional parameters should use this
+// instead of hard-coding the postgres "$n" style, so that the query
+// also runs against a Datastore built from NewDatastoreFromDriver with
+// a SQLiteDSN.
+func (ds Datastore) Placeholder(n int) string {
+	if ds.dialect == "sqlite" {
+		return "?"
+	}
+	return fmt.Sprintf("$%d", n)
+}
+
+// BeginTx starts a *sql.Tx using the Datastore's underlying *sql.DB.
+func (ds Datastore) BeginTx(ctx context.Context) (*sql.Tx, error) {
+	if ds.db == nil {
+		return nil, errs.E(errs.Code("nil_db"), errs.Database, "nil db")
+	}
+
+	tx, err := ds.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, errs.E(errs.Database, err)
+	}
+	return tx, nil
+}
+
+// RollbackTx rolls back the given transaction, wrapping and returning
+// originalErr alongside any error from the rollback itself. RollbackTx
+// always returns a non-nil *errs.Error, as it is only ever called on an
+// error path.
+func (ds Datastore) RollbackTx(tx *sql.Tx, originalErr error) error {
+	if tx == nil {
+		return errs.E(errs.Code("nil_tx"), errs.Database, fmt.Errorf("nil tx, original error: %w", originalErr))
+	}
+
+	if err := tx.Rollback(); err != nil {
+		return errs.E(errs.Code("rollback_err"), errs.Database, fmt.Errorf("rollback error: %v, original error: %w", err, originalErr))
+	}
+
+	return errs.E(errs.Database, originalErr)
+}
+
+// CommitTx commits the given transaction.
+func (ds Datastore) CommitTx(tx *sql.Tx) error {
+	if err := tx.Commit(); err != nil {
+		return errs.E(errs.Database, err)
+	}
+	return nil
+}
+
+// NewNullString returns a valid sql.NullString unless s is empty, in
+// which case the returned value is invalid (NULL).
+func NewNullString(s string) sql.NullString {
+	if s == "" {
+		return sql.NullString{}
+	}
+	return sql.NullString{String: s, Valid: true}
+}
+
+// NewNullInt64 returns a valid sql.NullInt64 unless i is the zero
+// value, in which case the returned value is invalid (NULL).
+func NewNullInt64(i int64) sql.NullInt64 {
+	if i == 0 {
+		return sql.NullInt64{}
+	}
+	return sql.NullInt64{Int64: i, Valid: true}
+}
@@ -3,6 +3,7 @@ package datastore
 import (
 	"context"
 	"database/sql"
+	"flag"
 	"os"
 	"reflect"
 	"testing"
@@ -14,6 +15,70 @@ import (
 	"github.com/gilcrest/go-api-basic/domain/logger"
 )
 
+// dbFlag selects which Driver the tests in this file open a connection
+// against. It defaults to sqlite, an in-memory database that needs
+// nothing else running, so a plain `go test ./...` works out of the
+// box in CI and for a new contributor. A developer who wants to run
+// the suite against a real PostgreSQL instead can opt in with
+//
+//	go test ./datastore/... -db=postgres
+//
+// The DATASTORE_TEST_DB environment variable is equivalent to -db and
+// is overridden by it.
+var dbFlag = flag.String("db", envOr("DATASTORE_TEST_DB", "sqlite"), "database driver to test against: postgres|sqlite")
+
+func envOr(key, def string) string {
+	if v := os.Getenv(key); v != "" {
+		return v
+	}
+	return def
+}
+
+// testDriver returns the Driver selected by dbFlag.
+func testDriver(t *testing.T) Driver {
+	t.Helper()
+	switch *dbFlag {
+	case "sqlite":
+		return NewSQLiteDSN(":memory:")
+	case "postgres":
+		return NewPostgreSQLDSN("localhost", "go_api_basic", "postgres", "", 5432)
+	default:
+		t.Fatalf("unknown -db value %q, want postgres|sqlite", *dbFlag)
+		return nil
+	}
+}
+
+// testDialect returns the Dialect of the Driver selected by dbFlag, for
+// tests that need to branch on it directly (e.g. to build dialect-
+// appropriate DDL) rather than going through a Datastore.
+func testDialect(t *testing.T) string {
+	t.Helper()
+	return testDriver(t).Dialect()
+}
+
+// testDB opens testDriver's Driver and registers its cleanup with t.
+func testDB(t *testing.T, lgr zerolog.Logger) *sql.DB {
+	t.Helper()
+	db, cleanup, err := testDriver(t).Open(lgr)
+	t.Cleanup(cleanup)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db
+}
+
+// testDBWithCleanup is testDB for tests that need to invoke the
+// cleanup function themselves, e.g. to verify behavior against an
+// already-closed *sql.DB.
+func testDBWithCleanup(t *testing.T, lgr zerolog.Logger) (db *sql.DB, cleanup func()) {
+	t.Helper()
+	db, cleanup, err := testDriver(t).Open(lgr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return db, cleanup
+}
+
 func TestNewPostgreSQLDSN(t *testing.T) {
 	c := qt.New(t)
 
@@ -67,11 +132,7 @@ func TestDatastore_DB(t *testing.T) {
 
 	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
 
-	ogdb, cleanup, err := NewPostgreSQLDB(NewPostgreSQLDSN("localhost", "go_api_basic", "postgres", "", 5432), lgr)
-	t.Cleanup(cleanup)
-	if err != nil {
-		t.Fatal(err)
-	}
+	ogdb := testDB(t, lgr)
 	ds := Datastore{db: ogdb}
 	db := ds.DB()
 
@@ -83,11 +144,7 @@ func TestNewDatastore(t *testing.T) {
 
 	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
 
-	db, cleanup, err := NewPostgreSQLDB(NewPostgreSQLDSN("localhost", "go_api_basic", "postgres", "", 5432), lgr)
-	t.Cleanup(cleanup)
-	if err != nil {
-		t.Fatal(err)
-	}
+	db := testDB(t, lgr)
 	got := NewDatastore(db)
 
 	want := Datastore{db: db}
@@ -126,13 +183,9 @@ func TestDatastore_BeginTx(t *testing.T) {
 		ctx context.Context
 	}
 
-	dsn := NewPostgreSQLDSN("localhost", "go_api_basic", "postgres", "", 5432)
 	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
 
-	db, cleanup, dberr := NewPostgreSQLDB(dsn, lgr)
-	if dberr != nil {
-		t.Errorf("datastore.NewPostgreSQLDB error = %v", dberr)
-	}
+	db, cleanup := testDBWithCleanup(t, lgr)
 	ctx := context.Background()
 	tests := []struct {
 		name    string
@@ -175,14 +228,13 @@ func TestDatastore_RollbackTx(t *testing.T) {
 		err error
 	}
 
-	dsn := NewPostgreSQLDSN("localhost", "go_api_basic", "postgres", "", 5432)
 	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
 
-	db, cleanup, err := NewPostgreSQLDB(dsn, lgr)
-	t.Cleanup(cleanup)
-	if err != nil {
-		t.Errorf("datastore.NewPostgreSQLDB error = %v", err)
-	}
+	db := testDB(t, lgr)
+	// tx and tx2 are open at once; lift the single-connection limit an
+	// in-memory sqlite pool is opened with so the second BeginTx does
+	// not wait forever for the first connection.
+	db.SetMaxOpenConns(0)
 	ctx := context.Background()
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
@@ -236,14 +288,11 @@ func TestDatastore_CommitTx(t *testing.T) {
 	type args struct {
 		tx *sql.Tx
 	}
-	dsn := NewPostgreSQLDSN("localhost", "go_api_basic", "postgres", "", 5432)
 	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
 
-	db, cleanup, err := NewPostgreSQLDB(dsn, lgr)
-	t.Cleanup(cleanup)
-	if err != nil {
-		t.Errorf("datastore.NewPostgreSQLDB error = %v", err)
-	}
+	db := testDB(t, lgr)
+	// tx, tx2 and tx3 overlap; see TestDatastore_RollbackTx.
+	db.SetMaxOpenConns(0)
 	ctx := context.Background()
 	tx, err := db.BeginTx(ctx, nil)
 	if err != nil {
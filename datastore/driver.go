@@ -0,0 +1,122 @@
+package datastore
+
+import (
+	"database/sql"
+
+	// pq registers the "postgres" database/sql driver.
+	_ "github.com/lib/pq"
+	// sqlite registers the "sqlite" database/sql driver.
+	_ "modernc.org/sqlite"
+
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/go-api-basic/domain/errs"
+)
+
+// Driver knows how to describe and open a database connection for a
+// particular database/sql driver. PostgreSQLDSN and SQLiteDSN are the
+// two implementations provided by this package.
+type Driver interface {
+	// DSN returns the driver-specific connection string.
+	DSN() string
+	// Open opens a connection pool for the driver's DSN, pings it to
+	// verify connectivity, and returns it along with a cleanup function
+	// that closes the pool.
+	Open(lgr zerolog.Logger) (db *sql.DB, cleanup func(), err error)
+	// Dialect identifies the SQL dialect the driver speaks, e.g.
+	// "postgres" or "sqlite", so callers can branch on dialect-specific
+	// syntax such as ON CONFLICT vs. ON DUPLICATE KEY.
+	Dialect() string
+}
+
+// Open implements Driver for PostgreSQLDSN.
+func (dsn PostgreSQLDSN) Open(lgr zerolog.Logger) (db *sql.DB, cleanup func(), err error) {
+	db, err = sql.Open("postgres", dsn.String())
+	if err != nil {
+		return nil, func() {}, errs.E(errs.Database, err)
+	}
+
+	cleanup = func() {
+		lgr.Info().Msg("closing database connection")
+		if err := db.Close(); err != nil {
+			lgr.Error().Err(err).Msg("error closing database connection")
+		}
+	}
+
+	if err := db.Ping(); err != nil {
+		return db, cleanup, errs.E(errs.Database, err)
+	}
+
+	return db, cleanup, nil
+}
+
+// Dialect implements Driver for PostgreSQLDSN.
+func (dsn PostgreSQLDSN) Dialect() string { return "postgres" }
+
+// DSN implements Driver for PostgreSQLDSN.
+func (dsn PostgreSQLDSN) DSN() string { return dsn.String() }
+
+// SQLiteDSN identifies a SQLite database file (or ":memory:") for use
+// in tests and local development, where running a full PostgreSQL
+// instance is impractical.
+type SQLiteDSN struct {
+	Path string
+}
+
+// NewSQLiteDSN is a constructor for SQLiteDSN.
+func NewSQLiteDSN(path string) SQLiteDSN {
+	return SQLiteDSN{Path: path}
+}
+
+// DSN implements Driver for SQLiteDSN.
+func (dsn SQLiteDSN) DSN() string { return dsn.Path }
+
+// Dialect implements Driver for SQLiteDSN.
+func (dsn SQLiteDSN) Dialect() string { return "sqlite" }
+
+// Open implements Driver for SQLiteDSN.
+func (dsn SQLiteDSN) Open(lgr zerolog.Logger) (db *sql.DB, cleanup func(), err error) {
+	db, err = sql.Open("sqlite", dsn.Path)
+	if err != nil {
+		return nil, func() {}, errs.E(errs.Database, err)
+	}
+
+	if dsn.Path == ":memory:" {
+		// database/sql's pool would otherwise hand out a second
+		// connection to a concurrent caller, and each new connection to
+		// ":memory:" is a distinct, schema-less SQLite database, so a
+		// second connection sees none of the first's tables.
+		db.SetMaxOpenConns(1)
+	}
+
+	cleanup = func() {
+		lgr.Info().Msg("closing database connection")
+		if err := db.Close(); err != nil {
+			lgr.Error().Err(err).Msg("error closing database connection")
+		}
+	}
+
+	if err := db.Ping(); err != nil {
+		return db, cleanup, errs.E(errs.Database, err)
+	}
+
+	return db, cleanup, nil
+}
+
+// NewSQLiteDB opens dsn and returns its *sql.DB along with a cleanup
+// function, mirroring NewPostgreSQLDB.
+func NewSQLiteDB(dsn SQLiteDSN, lgr zerolog.Logger) (db *sql.DB, cleanup func(), err error) {
+	return dsn.Open(lgr)
+}
+
+// NewDatastoreFromDriver opens d and wraps the resulting *sql.DB in a
+// Datastore, recording d's dialect so that Datastore.Dialect can later
+// report it.
+func NewDatastoreFromDriver(d Driver, lgr zerolog.Logger) (Datastore, func(), error) {
+	db, cleanup, err := d.Open(lgr)
+	if err != nil {
+		return Datastore{}, cleanup, err
+	}
+
+	return Datastore{db: db, dialect: d.Dialect()}, cleanup, nil
+}
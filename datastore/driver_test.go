@@ -0,0 +1,35 @@
+package datastore
+
+import (
+	"os"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/go-api-basic/domain/logger"
+)
+
+func TestPostgreSQLDSN_Dialect(t *testing.T) {
+	c := qt.New(t)
+	dsn := NewPostgreSQLDSN("localhost", "go_api_basic", "postgres", "", 5432)
+	c.Assert(dsn.Dialect(), qt.Equals, "postgres")
+}
+
+func TestSQLiteDSN_Dialect(t *testing.T) {
+	c := qt.New(t)
+	dsn := NewSQLiteDSN(":memory:")
+	c.Assert(dsn.Dialect(), qt.Equals, "sqlite")
+	c.Assert(dsn.DSN(), qt.Equals, ":memory:")
+}
+
+func TestNewDatastoreFromDriver(t *testing.T) {
+	c := qt.New(t)
+	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
+
+	ds, cleanup, err := NewDatastoreFromDriver(testDriver(t), lgr)
+	t.Cleanup(cleanup)
+	c.Assert(err, qt.IsNil)
+	c.Assert(ds.DB(), qt.Not(qt.IsNil))
+	c.Assert(ds.Dialect(), qt.Equals, *dbFlag)
+}
@@ -0,0 +1,104 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"github.com/gilcrest/go-api-basic/domain/errs"
+)
+
+// txCtxKey is the unexported context.Context key under which the
+// ambient transaction for a request is stored.
+type txCtxKey struct{}
+
+// txWrapper tracks the transaction a WithTx call is operating on, plus
+// how many SAVEPOINTs nested WithTx calls have pushed on top of it.
+type txWrapper struct {
+	tx    *sql.Tx
+	depth int
+}
+
+// TxFromContext returns the ambient *sql.Tx stored in ctx by WithTx,
+// if any. Repository code can use this to run its queries inside
+// whatever transaction (if any) the caller started, without taking
+// *sql.Tx as an explicit parameter.
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	w, ok := ctx.Value(txCtxKey{}).(*txWrapper)
+	if !ok {
+		return nil, false
+	}
+	return w.tx, true
+}
+
+// WithTx begins a transaction, invokes fn with a context carrying that
+// transaction (retrievable via TxFromContext) and the *sql.Tx itself,
+// and commits on a nil return, or rolls back if fn returns an error or
+// panics (the panic is re-raised after the rollback completes).
+//
+// If ctx already carries an ambient transaction — because this call is
+// nested inside another WithTx call, directly or via a repository
+// function further down the stack that just forwarded ctx — WithTx
+// does not start a new transaction. Instead it pushes a Postgres
+// SAVEPOINT, RELEASEing it on success or issuing ROLLBACK TO on
+// failure, so the outer transaction can still commit independently of
+// the inner one's outcome.
+func (ds Datastore) WithTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error) (err error) {
+	if w, ok := ctx.Value(txCtxKey{}).(*txWrapper); ok {
+		return ds.withSavepoint(ctx, w, fn)
+	}
+
+	tx, err := ds.BeginTx(ctx)
+	if err != nil {
+		return err
+	}
+
+	txCtx := context.WithValue(ctx, txCtxKey{}, &txWrapper{tx: tx})
+
+	defer func() {
+		if p := recover(); p != nil {
+			_ = ds.RollbackTx(tx, fmt.Errorf("panic in WithTx: %v", p))
+			panic(p)
+		}
+	}()
+
+	if err = fn(txCtx, tx); err != nil {
+		return ds.RollbackTx(tx, err)
+	}
+
+	return ds.CommitTx(tx)
+}
+
+// withSavepoint runs fn inside a SAVEPOINT nested within w's
+// transaction, releasing or rolling back to the savepoint depending on
+// fn's outcome. It never starts or ends w's underlying transaction;
+// that remains the responsibility of the outermost WithTx call.
+func (ds Datastore) withSavepoint(ctx context.Context, w *txWrapper, fn func(ctx context.Context, tx *sql.Tx) error) (err error) {
+	w.depth++
+	sp := fmt.Sprintf("sp_%d", w.depth)
+	defer func() { w.depth-- }()
+
+	if _, err := w.tx.ExecContext(ctx, "SAVEPOINT "+sp); err != nil {
+		return errs.E(errs.Database, err)
+	}
+
+	defer func() {
+		if p := recover(); p != nil {
+			_, _ = w.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+sp)
+			panic(p)
+		}
+	}()
+
+	if err = fn(ctx, w.tx); err != nil {
+		if _, rerr := w.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+sp); rerr != nil {
+			return errs.E(errs.Code("rollback_err"), errs.Database, fmt.Errorf("rollback to savepoint error: %v, original error: %w", rerr, err))
+		}
+		return errs.E(errs.Database, err)
+	}
+
+	if _, err := w.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+sp); err != nil {
+		return errs.E(errs.Database, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,137 @@
+package datastore
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"os"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+	"github.com/rs/zerolog"
+
+	"github.com/gilcrest/go-api-basic/domain/logger"
+)
+
+// withTxTestTable creates a scratch table for WithTx tests to insert
+// into, dropping it on cleanup. The DDL is dialect-aware since
+// "bigserial" is Postgres-specific.
+func withTxTestTable(t *testing.T, db *sql.DB, dialect string) {
+	t.Helper()
+	ddl := `CREATE TABLE IF NOT EXISTS with_tx_test (id bigserial PRIMARY KEY, name text NOT NULL)`
+	if dialect == "sqlite" {
+		ddl = `CREATE TABLE IF NOT EXISTS with_tx_test (id INTEGER PRIMARY KEY AUTOINCREMENT, name text NOT NULL)`
+	}
+	_, err := db.Exec(ddl)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		_, _ = db.Exec(`DROP TABLE IF EXISTS with_tx_test`)
+	})
+}
+
+func rowCount(t *testing.T, ds Datastore, name string) int {
+	t.Helper()
+	query := fmt.Sprintf(`SELECT count(*) FROM with_tx_test WHERE name = %s`, ds.Placeholder(1))
+	var n int
+	if err := ds.DB().QueryRow(query, name).Scan(&n); err != nil {
+		t.Fatal(err)
+	}
+	return n
+}
+
+func TestDatastore_WithTx_Commit(t *testing.T) {
+	c := qt.New(t)
+	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
+	dialect := testDialect(t)
+	db := testDB(t, lgr)
+	withTxTestTable(t, db, dialect)
+	ds := Datastore{db: db, dialect: dialect}
+	ctx := context.Background()
+
+	err := ds.WithTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		_, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO with_tx_test (name) VALUES (%s)`, ds.Placeholder(1)), "commit")
+		return err
+	})
+
+	c.Assert(err, qt.IsNil)
+	c.Assert(rowCount(t, ds, "commit"), qt.Equals, 1)
+}
+
+func TestDatastore_WithTx_ErrorRollback(t *testing.T) {
+	c := qt.New(t)
+	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
+	dialect := testDialect(t)
+	db := testDB(t, lgr)
+	withTxTestTable(t, db, dialect)
+	ds := Datastore{db: db, dialect: dialect}
+	ctx := context.Background()
+
+	wantErr := errors.New("boom")
+	err := ds.WithTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO with_tx_test (name) VALUES (%s)`, ds.Placeholder(1)), "rollback"); err != nil {
+			return err
+		}
+		return wantErr
+	})
+
+	c.Assert(err, qt.Not(qt.IsNil))
+	c.Assert(rowCount(t, ds, "rollback"), qt.Equals, 0)
+}
+
+func TestDatastore_WithTx_PanicRollback(t *testing.T) {
+	c := qt.New(t)
+	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
+	dialect := testDialect(t)
+	db := testDB(t, lgr)
+	withTxTestTable(t, db, dialect)
+	ds := Datastore{db: db, dialect: dialect}
+	ctx := context.Background()
+
+	defer func() {
+		p := recover()
+		c.Assert(p, qt.Equals, "kaboom")
+		c.Assert(rowCount(t, ds, "panic"), qt.Equals, 0)
+	}()
+
+	_ = ds.WithTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		_, _ = tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO with_tx_test (name) VALUES (%s)`, ds.Placeholder(1)), "panic")
+		panic("kaboom")
+	})
+
+	t.Fatal("WithTx() should have propagated the panic")
+}
+
+func TestDatastore_WithTx_NestedSavepointRollback(t *testing.T) {
+	c := qt.New(t)
+	lgr := logger.NewLogger(os.Stdout, zerolog.DebugLevel, true)
+	dialect := testDialect(t)
+	db := testDB(t, lgr)
+	withTxTestTable(t, db, dialect)
+	ds := Datastore{db: db, dialect: dialect}
+	ctx := context.Background()
+
+	err := ds.WithTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+		if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO with_tx_test (name) VALUES (%s)`, ds.Placeholder(1)), "outer"); err != nil {
+			return err
+		}
+
+		innerErr := ds.WithTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+			if _, err := tx.ExecContext(ctx, fmt.Sprintf(`INSERT INTO with_tx_test (name) VALUES (%s)`, ds.Placeholder(1)), "inner"); err != nil {
+				return err
+			}
+			return errors.New("inner failure")
+		})
+		if innerErr == nil {
+			t.Fatal("nested WithTx() should have returned an error")
+		}
+
+		return nil
+	})
+
+	c.Assert(err, qt.IsNil)
+	c.Assert(rowCount(t, ds, "outer"), qt.Equals, 1)
+	c.Assert(rowCount(t, ds, "inner"), qt.Equals, 0)
+}
@@ -0,0 +1,225 @@
+// Package errs defines the application's error type. The design follows
+// the approach described in Rob Pike's "Errors are values" / Upspin error
+// package: a single Error type that can be built up with the E function
+// from a set of well known argument types, so that callers can layer
+// context onto an error as it propagates without losing the original
+// cause or the HTTP semantics it implies.
+package errs
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+)
+
+// Kind defines the kind of error this is, mostly for use in the
+// HTTP error response, but can also be used to match errors with
+// Match.
+type Kind uint8
+
+// Kinds of errors.
+const (
+	Other           Kind = iota // Unclassified error. This value is not printed in the error message.
+	Invalid                     // Invalid operation for this type of item.
+	InvalidRequest              // Invalid request body/headers/etc.
+	Permission                  // Permission denied.
+	Unauthenticated             // Request lacks valid authentication credentials.
+	Unauthorized                // User does not have permission to perform this operation.
+	Exist                       // Item already exists.
+	NotExist                    // Item does not exist.
+	Internal                    // Internal error or inconsistency.
+	Database                    // Error from the database.
+	Validation                  // Input validation error.
+	Unanticipated               // Unanticipated error.
+)
+
+// String returns the string representation of a Kind.
+func (k Kind) String() string {
+	switch k {
+	case Other:
+		return "other_error"
+	case Invalid:
+		return "invalid"
+	case InvalidRequest:
+		return "invalid_request"
+	case Permission:
+		return "permission"
+	case Unauthenticated:
+		return "unauthenticated"
+	case Unauthorized:
+		return "unauthorized"
+	case Exist:
+		return "already_exists"
+	case NotExist:
+		return "does_not_exist"
+	case Internal:
+		return "internal"
+	case Database:
+		return "database_error"
+	case Validation:
+		return "validation_error"
+	case Unanticipated:
+		return "unanticipated_error"
+	}
+	return "unknown_error_kind"
+}
+
+// Code is a human-readable, short representation of the error, safe to
+// expose to the caller (e.g. "invalid_token").
+type Code string
+
+// Op describes an operation, usually as "package.Function", that was
+// being performed when the error occurred.
+type Op string
+
+// Error is the type that implements the error interface. It contains
+// zero or more of the fields below, each of which may be used to print
+// or construct an application-specific error message or to match
+// against the Kind/Code.
+type Error struct {
+	// Op is the operation being performed, usually the name of the
+	// method being invoked.
+	Op Op
+	// Kind is the class of error, such as permission failure, or
+	// invalid request. It is used by the HTTP layer to choose a
+	// status code.
+	Kind Kind
+	// Code is a short, caller-safe code for the error.
+	Code Code
+	// The underlying error that triggered this one, if any.
+	Err error
+}
+
+func (e *Error) Error() string {
+	b := new(bytes.Buffer)
+	e.printStack(b)
+	return b.String()
+}
+
+// printStack writes e's message to b, recursing into e.Err when it is
+// itself an *Error. It is the sole writer of each Error's Op/Code/Kind
+// in the chain — it must never call e.Error() (which would call back
+// into printStack and duplicate whatever it already wrote).
+func (e *Error) printStack(b *bytes.Buffer) {
+	if e.Op != "" {
+		fmt.Fprintf(b, "%s: ", e.Op)
+	}
+
+	if e.Err != nil {
+		if prevErr, ok := e.Err.(*Error); ok {
+			prevErr.printStack(b)
+			return
+		}
+		b.WriteString(e.Err.Error())
+		return
+	}
+
+	if e.Code != "" {
+		fmt.Fprintf(b, "<%s> ", e.Code)
+	}
+	fmt.Fprintf(b, "%s", e.Kind)
+}
+
+// Unwrap allows errors.Is/errors.As to walk the chain of *Error values.
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// E builds an *Error from its arguments. There must be at least one
+// argument or E panics. The type of each argument determines its
+// meaning:
+//
+//	errs.Op         operation being performed
+//	errs.Kind        kind of error
+//	errs.Code        caller-safe code
+//	error           underlying error that triggered this one
+//	string          shorthand for errors.New(arg), useful for a leaf error
+//
+// If more than one argument of a given type is given, the last one wins.
+func E(args ...interface{}) error {
+	if len(args) == 0 {
+		panic("call to errs.E with no arguments")
+	}
+
+	e := &Error{}
+	for _, arg := range args {
+		switch a := arg.(type) {
+		case Op:
+			e.Op = a
+		case Kind:
+			e.Kind = a
+		case Code:
+			e.Code = a
+		case *Error:
+			cp := *a
+			e.Err = &cp
+		case error:
+			e.Err = a
+		case string:
+			e.Err = errors.New(a)
+		default:
+			panic(fmt.Sprintf("errs.E: bad call argument type %T", a))
+		}
+	}
+
+	if prev, ok := e.Err.(*Error); ok {
+		if e.Kind == Other {
+			e.Kind = prev.Kind
+		}
+		if e.Code == "" {
+			e.Code = prev.Code
+		}
+	}
+
+	return e
+}
+
+// KindText returns the text for a given error's Kind.
+func KindText(err error) string {
+	e, ok := err.(*Error)
+	if !ok {
+		return Other.String()
+	}
+	if e.Kind != Other {
+		return e.Kind.String()
+	}
+	if e.Err != nil {
+		return KindText(e.Err)
+	}
+	return Other.String()
+}
+
+// Match compares actual against template. It can be used to check
+// expected errors in tests, called as Match(actual, template) — e.g.
+// Match(err, errs.E(errs.NotExist)). The underlying types must both be
+// *Error, and all fields set on template must match the corresponding
+// field of actual; fields left zero on template are ignored. If
+// template's Err field is set, it is compared recursively.
+func Match(actual, template error) bool {
+	a, ok := actual.(*Error)
+	if !ok {
+		return false
+	}
+	tmpl, ok := template.(*Error)
+	if !ok {
+		return false
+	}
+	if tmpl.Op != "" && tmpl.Op != a.Op {
+		return false
+	}
+	if tmpl.Kind != Other && tmpl.Kind != a.Kind {
+		return false
+	}
+	if tmpl.Code != "" && tmpl.Code != a.Code {
+		return false
+	}
+	if tmpl.Err != nil {
+		if _, ok := tmpl.Err.(*Error); ok {
+			return Match(a.Err, tmpl.Err)
+		}
+		if a.Err == nil || tmpl.Err.Error() != a.Err.Error() {
+			return false
+		}
+	}
+	return true
+}
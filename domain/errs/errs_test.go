@@ -0,0 +1,57 @@
+package errs
+
+import (
+	"errors"
+	"testing"
+
+	qt "github.com/frankban/quicktest"
+)
+
+func TestError_Error(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+		want string
+	}{
+		{
+			name: "leaf with op and code",
+			err:  E(Op("pkg.Func"), Database, Code("db_err"), errors.New("connection refused")),
+			want: "pkg.Func: connection refused",
+		},
+		{
+			name: "no op, no wrapped err",
+			err:  E(Database),
+			want: "database_error",
+		},
+		{
+			name: "no op, no wrapped err, with code",
+			err:  E(Database, Code("db_err")),
+			want: "<db_err> database_error",
+		},
+		{
+			name: "one level of Op wrapping a plain error",
+			err:  E(Op("auth/TokenStore.AddUser"), Database, errors.New("sql: connection refused")),
+			want: "auth/TokenStore.AddUser: sql: connection refused",
+		},
+		{
+			// Regression test: wrapping an *Error that already has an Op
+			// set must not print that Op twice. This is exactly the
+			// shape RollbackTx produces when it re-wraps an error that
+			// AddUser already tagged with its Op.
+			name: "two levels deep, inner Op is printed exactly once",
+			err:  E(Database, E(Op("auth/TokenStore.AddUser"), Database, errors.New("sql: connection refused"))),
+			want: "auth/TokenStore.AddUser: sql: connection refused",
+		},
+		{
+			name: "three levels deep, each Op is printed exactly once",
+			err:  E(Op("outer.Op"), E(Database, E(Op("inner.Op"), Database, errors.New("boom")))),
+			want: "outer.Op: inner.Op: boom",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c := qt.New(t)
+			c.Assert(tt.err.Error(), qt.Equals, tt.want)
+		})
+	}
+}
@@ -0,0 +1,113 @@
+package errs
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+
+	"github.com/rs/zerolog"
+)
+
+// errResponse is the JSON shape written to the client by
+// HTTPErrorResponse for any error of type *Error.
+type errResponse struct {
+	Error svcError `json:"error"`
+}
+
+// svcError represents the service error.
+type svcError struct {
+	Kind    string `json:"kind"`
+	Code    string `json:"code,omitempty"`
+	Message string `json:"message"`
+}
+
+// problemJSONMediaType is the RFC 7807 media type. HTTPErrorResponse
+// writes a Problem Details body instead of its default errResponse
+// shape when a request's Accept header contains it.
+const problemJSONMediaType = "application/problem+json"
+
+// problemDetails is the RFC 7807 "Problem Details for HTTP APIs" body.
+type problemDetails struct {
+	Type     string `json:"type"`
+	Title    string `json:"title"`
+	Status   int    `json:"status"`
+	Detail   string `json:"detail"`
+	Instance string `json:"instance,omitempty"`
+}
+
+// httpStatusCode maps an error Kind to an HTTP status code.
+func httpStatusCode(k Kind) int {
+	switch k {
+	case Invalid, InvalidRequest, Validation:
+		return http.StatusBadRequest
+	case Unauthenticated:
+		return http.StatusUnauthorized
+	case Unauthorized, Permission:
+		return http.StatusForbidden
+	case NotExist:
+		return http.StatusNotFound
+	case Exist:
+		return http.StatusConflict
+	case Database, Internal, Unanticipated, Other:
+		return http.StatusInternalServerError
+	}
+	return http.StatusInternalServerError
+}
+
+// HTTPErrorResponse takes an error and a response writer and writes the
+// error to the response as JSON. Errors that are not of type *Error
+// are treated as unanticipated internal errors and their details are
+// not leaked to the client. lgr is used to log the full error,
+// including any wrapped cause, before the (possibly trimmed) response
+// is written.
+//
+// When r's Accept header contains "application/problem+json",
+// the error is instead written as an RFC 7807 Problem Details body;
+// any other Accept value (including none) gets the existing
+// errResponse shape.
+func HTTPErrorResponse(w http.ResponseWriter, r *http.Request, lgr zerolog.Logger, err error) {
+	if err == nil {
+		return
+	}
+
+	e, ok := err.(*Error)
+	if !ok {
+		e = &Error{Kind: Unanticipated}
+	}
+
+	lgr.Error().Stack().Err(err).Msg(e.Kind.String())
+
+	status := httpStatusCode(e.Kind)
+
+	if r != nil && strings.Contains(r.Header.Get("Accept"), problemJSONMediaType) {
+		writeProblemJSON(w, r, status, e)
+		return
+	}
+
+	resp := errResponse{Error: svcError{
+		Kind:    e.Kind.String(),
+		Code:    string(e.Code),
+		Message: e.Error(),
+	}}
+
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+// writeProblemJSON writes e as an RFC 7807 application/problem+json
+// body. Type is a stable URN derived from the error Kind so that
+// clients can switch on it without parsing Title.
+func writeProblemJSON(w http.ResponseWriter, r *http.Request, status int, e *Error) {
+	p := problemDetails{
+		Type:     "urn:go-api-basic:error:" + e.Kind.String(),
+		Title:    http.StatusText(status),
+		Status:   status,
+		Detail:   e.Error(),
+		Instance: r.URL.Path,
+	}
+
+	w.Header().Set("Content-Type", problemJSONMediaType+"; charset=utf-8")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(p)
+}
@@ -0,0 +1,20 @@
+// Package logger provides a single constructor for the zerolog.Logger
+// used throughout the application.
+package logger
+
+import (
+	"io"
+
+	"github.com/rs/zerolog"
+)
+
+// NewLogger returns a zerolog.Logger that writes to w at the given level.
+// When pretty is true, output is formatted for human consumption via
+// zerolog.ConsoleWriter instead of structured JSON.
+func NewLogger(w io.Writer, level zerolog.Level, pretty bool) zerolog.Logger {
+	if pretty {
+		w = zerolog.ConsoleWriter{Out: w}
+	}
+
+	return zerolog.New(w).Level(level).With().Timestamp().Logger()
+}